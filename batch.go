@@ -0,0 +1,230 @@
+package dynamodb
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	awsdynamodb "github.com/aws/aws-sdk-go/service/dynamodb"
+
+	"github.com/philippgille/gokv/util"
+)
+
+// errBatchRetriesExceeded is returned when DynamoDB keeps returning unprocessed
+// keys/items after maxBatchRetries attempts.
+var errBatchRetriesExceeded = errors.New("dynamodb: giving up retrying unprocessed keys/items after too many attempts")
+
+// errBatchRequiresV1 is returned by the batch APIs when called on a Client
+// constructed via NewClientV2, which doesn't support them yet.
+var errBatchRequiresV1 = errors.New("dynamodb: batch operations are only supported on a Client created with NewClient, not NewClientV2")
+
+// maxBatchGetItems is the maximum number of items DynamoDB allows per BatchGetItem call.
+const maxBatchGetItems = 100
+
+// maxBatchWriteItems is the maximum number of items DynamoDB allows per BatchWriteItem call.
+const maxBatchWriteItems = 25
+
+// maxBatchRetries is the maximum number of times unprocessed keys/items are retried
+// before giving up and returning an error.
+const maxBatchRetries = 5
+
+// batchRetryBaseDelay is the base delay used for the exponential backoff between retries
+// of unprocessed keys/items.
+const batchRetryBaseDelay = 50 * time.Millisecond
+
+// GetMulti retrieves the stored values for the given keys using DynamoDB's BatchGetItem.
+// For every key that was found, out must contain a pointer to the value that the
+// retrieved data should be unmarshalled into, keyed by the same string.
+// The returned found map indicates, for each key, whether a value was found for it.
+// out itself is never modified; for keys that aren't found, the value their pointer
+// in out refers to is left untouched, and found has an entry set to false.
+func (c Client) GetMulti(keys []string, out map[string]interface{}) (found map[string]bool, err error) {
+	if c.v1svc == nil {
+		return nil, errBatchRequiresV1
+	}
+	found = make(map[string]bool, len(keys))
+	if len(keys) == 0 {
+		return found, nil
+	}
+	for _, k := range keys {
+		if err := util.CheckKey(k); err != nil {
+			return nil, err
+		}
+		found[k] = false
+	}
+
+	for _, chunk := range chunkStrings(keys, maxBatchGetItems) {
+		keysAndAttributes := &awsdynamodb.KeysAndAttributes{
+			Keys: make([]map[string]*awsdynamodb.AttributeValue, len(chunk)),
+		}
+		for i, k := range chunk {
+			k := k
+			keysAndAttributes.Keys[i] = map[string]*awsdynamodb.AttributeValue{
+				keyAttrName: {S: &k},
+			}
+		}
+		requestItems := map[string]*awsdynamodb.KeysAndAttributes{
+			c.tableName: keysAndAttributes,
+		}
+
+		for attempt := 0; ; attempt++ {
+			output, err := c.v1svc.BatchGetItem(&awsdynamodb.BatchGetItemInput{
+				RequestItems: requestItems,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			for _, item := range output.Responses[c.tableName] {
+				k := *item[keyAttrName].S
+				attributeVal := item[valAttrName]
+				if attributeVal == nil {
+					continue
+				}
+				found[k] = true
+				if target, ok := out[k]; ok {
+					if err := c.codec.Unmarshal(attributeVal.B, target); err != nil {
+						return nil, err
+					}
+				}
+			}
+
+			unprocessed := output.UnprocessedKeys[c.tableName]
+			if unprocessed == nil || len(unprocessed.Keys) == 0 {
+				break
+			}
+			if attempt >= maxBatchRetries {
+				return nil, errBatchRetriesExceeded
+			}
+			time.Sleep(batchRetryBaseDelay << uint(attempt))
+			requestItems = map[string]*awsdynamodb.KeysAndAttributes{c.tableName: unprocessed}
+		}
+	}
+
+	return found, nil
+}
+
+// SetMulti stores the given values using DynamoDB's BatchWriteItem.
+// Values are automatically marshalled to JSON or gob (depending on the configuration).
+// No key may be "" and no value may be nil.
+func (c Client) SetMulti(items map[string]interface{}) error {
+	if c.v1svc == nil {
+		return errBatchRequiresV1
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(items))
+	writeRequests := make([]*awsdynamodb.WriteRequest, 0, len(items))
+	for k, v := range items {
+		if err := util.CheckKeyAndValue(k, v); err != nil {
+			return err
+		}
+		data, err := c.codec.Marshal(v)
+		if err != nil {
+			return err
+		}
+		k := k
+		item := map[string]*awsdynamodb.AttributeValue{
+			keyAttrName: {S: &k},
+			valAttrName: {B: data},
+		}
+		if c.ttl > 0 {
+			epoch := strconv.FormatInt(time.Now().Add(c.ttl).Unix(), 10)
+			item[ttlAttrName] = &awsdynamodb.AttributeValue{N: &epoch}
+		}
+		writeRequests = append(writeRequests, &awsdynamodb.WriteRequest{
+			PutRequest: &awsdynamodb.PutRequest{
+				Item: item,
+			},
+		})
+		keys = append(keys, k)
+	}
+
+	for _, chunk := range chunkWriteRequests(writeRequests, maxBatchWriteItems) {
+		if err := c.batchWrite(chunk); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteMulti deletes the stored values for the given keys using DynamoDB's BatchWriteItem.
+// Deleting a non-existing key-value pair does NOT lead to an error.
+// No key may be "".
+func (c Client) DeleteMulti(keys []string) error {
+	if c.v1svc == nil {
+		return errBatchRequiresV1
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	writeRequests := make([]*awsdynamodb.WriteRequest, len(keys))
+	for i, k := range keys {
+		if err := util.CheckKey(k); err != nil {
+			return err
+		}
+		k := k
+		writeRequests[i] = &awsdynamodb.WriteRequest{
+			DeleteRequest: &awsdynamodb.DeleteRequest{
+				Key: map[string]*awsdynamodb.AttributeValue{
+					keyAttrName: {S: &k},
+				},
+			},
+		}
+	}
+
+	for _, chunk := range chunkWriteRequests(writeRequests, maxBatchWriteItems) {
+		if err := c.batchWrite(chunk); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// batchWrite issues a single BatchWriteItem call for the given write requests,
+// retrying any UnprocessedItems with exponential backoff.
+func (c Client) batchWrite(writeRequests []*awsdynamodb.WriteRequest) error {
+	requestItems := map[string][]*awsdynamodb.WriteRequest{c.tableName: writeRequests}
+
+	for attempt := 0; ; attempt++ {
+		output, err := c.v1svc.BatchWriteItem(&awsdynamodb.BatchWriteItemInput{
+			RequestItems: requestItems,
+		})
+		if err != nil {
+			return err
+		}
+
+		unprocessed := output.UnprocessedItems[c.tableName]
+		if len(unprocessed) == 0 {
+			return nil
+		}
+		if attempt >= maxBatchRetries {
+			return errBatchRetriesExceeded
+		}
+		time.Sleep(batchRetryBaseDelay << uint(attempt))
+		requestItems = map[string][]*awsdynamodb.WriteRequest{c.tableName: unprocessed}
+	}
+}
+
+// chunkStrings splits keys into slices of at most size elements.
+func chunkStrings(keys []string, size int) [][]string {
+	var chunks [][]string
+	for size < len(keys) {
+		keys, chunks = keys[size:], append(chunks, keys[0:size:size])
+	}
+	return append(chunks, keys)
+}
+
+// chunkWriteRequests splits requests into slices of at most size elements.
+func chunkWriteRequests(requests []*awsdynamodb.WriteRequest, size int) [][]*awsdynamodb.WriteRequest {
+	var chunks [][]*awsdynamodb.WriteRequest
+	for size < len(requests) {
+		requests, chunks = requests[size:], append(chunks, requests[0:size:size])
+	}
+	return append(chunks, requests)
+}
@@ -0,0 +1,136 @@
+package dynamodb
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	. "github.com/onsi/gomega"
+)
+
+// batchMockDynamoDB is an in-memory DynamoDB double for the batch APIs. When
+// unprocessOnce is set, the first BatchGetItem/BatchWriteItem call leaves one
+// request unprocessed, so tests can exercise the retry path.
+type batchMockDynamoDB struct {
+	dynamodbiface.DynamoDBAPI
+	items         map[string]map[string]*dynamodb.AttributeValue
+	getCalls      int
+	writeCalls    int
+	unprocessOnce bool
+}
+
+func newBatchMockDynamoDB() *batchMockDynamoDB {
+	return &batchMockDynamoDB{items: map[string]map[string]*dynamodb.AttributeValue{}}
+}
+
+func (m *batchMockDynamoDB) DescribeTableWithContext(_ aws.Context, i *dynamodb.DescribeTableInput, _ ...request.Option) (*dynamodb.DescribeTableOutput, error) {
+	return &dynamodb.DescribeTableOutput{Table: &dynamodb.TableDescription{TableName: i.TableName}}, nil
+}
+
+func (m *batchMockDynamoDB) BatchGetItem(i *dynamodb.BatchGetItemInput) (*dynamodb.BatchGetItemOutput, error) {
+	m.getCalls++
+	keys := i.RequestItems["t"].Keys
+	output := &dynamodb.BatchGetItemOutput{Responses: map[string][]map[string]*dynamodb.AttributeValue{}}
+
+	if m.unprocessOnce && m.getCalls == 1 && len(keys) > 0 {
+		unprocessed := keys[len(keys)-1:]
+		keys = keys[:len(keys)-1]
+		output.UnprocessedKeys = map[string]*dynamodb.KeysAndAttributes{"t": {Keys: unprocessed}}
+	}
+
+	for _, key := range keys {
+		if item, ok := m.items[*key[keyAttrName].S]; ok {
+			output.Responses["t"] = append(output.Responses["t"], item)
+		}
+	}
+	return output, nil
+}
+
+func (m *batchMockDynamoDB) BatchWriteItem(i *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+	m.writeCalls++
+	requests := i.RequestItems["t"]
+	output := &dynamodb.BatchWriteItemOutput{}
+
+	if m.unprocessOnce && m.writeCalls == 1 && len(requests) > 0 {
+		unprocessed := requests[len(requests)-1:]
+		requests = requests[:len(requests)-1]
+		output.UnprocessedItems = map[string][]*dynamodb.WriteRequest{"t": unprocessed}
+	}
+
+	for _, req := range requests {
+		switch {
+		case req.PutRequest != nil:
+			m.items[*req.PutRequest.Item[keyAttrName].S] = req.PutRequest.Item
+		case req.DeleteRequest != nil:
+			delete(m.items, *req.DeleteRequest.Key[keyAttrName].S)
+		}
+	}
+	return output, nil
+}
+
+func TestSetMultiGetMultiDeleteMulti(t *testing.T) {
+	g := NewGomegaWithT(t)
+	svc := newBatchMockDynamoDB()
+	c, err := NewClient(Options{Service: svc, TableName: "t"})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// More items than maxBatchWriteItems, to exercise chunking.
+	items := make(map[string]interface{}, maxBatchWriteItems+5)
+	keys := make([]string, 0, maxBatchWriteItems+5)
+	for i := 0; i < maxBatchWriteItems+5; i++ {
+		k := fmt.Sprintf("k%d", i)
+		items[k] = i
+		keys = append(keys, k)
+	}
+	g.Expect(c.SetMulti(items)).To(Succeed())
+	g.Expect(svc.writeCalls).To(Equal(2))
+
+	out := make(map[string]interface{}, len(keys))
+	for _, k := range keys {
+		var v int
+		out[k] = &v
+	}
+	found, err := c.GetMulti(keys, out)
+	g.Expect(err).NotTo(HaveOccurred())
+	for _, k := range keys {
+		g.Expect(found[k]).To(BeTrue())
+	}
+
+	g.Expect(c.DeleteMulti(keys)).To(Succeed())
+	g.Expect(svc.items).To(BeEmpty())
+}
+
+func TestGetMultiRetriesUnprocessedKeys(t *testing.T) {
+	g := NewGomegaWithT(t)
+	svc := newBatchMockDynamoDB()
+	svc.unprocessOnce = true
+	c, err := NewClient(Options{Service: svc, TableName: "t"})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(c.SetMulti(map[string]interface{}{"k1": "v1", "k2": "v2"})).To(Succeed())
+
+	out := map[string]interface{}{"k1": new(string), "k2": new(string)}
+	found, err := c.GetMulti([]string{"k1", "k2"}, out)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(found["k1"]).To(BeTrue())
+	g.Expect(found["k2"]).To(BeTrue())
+	g.Expect(svc.getCalls).To(BeNumerically(">=", 2))
+}
+
+func TestGetMultiSkipsItemsMissingValue(t *testing.T) {
+	g := NewGomegaWithT(t)
+	svc := newBatchMockDynamoDB()
+	svc.items["k1"] = map[string]*dynamodb.AttributeValue{
+		keyAttrName: {S: aws.String("k1")},
+	}
+	c, err := NewClient(Options{Service: svc, TableName: "t"})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	out := map[string]interface{}{"k1": new(string)}
+	found, err := c.GetMulti([]string{"k1"}, out)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(found["k1"]).To(BeFalse())
+}
@@ -0,0 +1,211 @@
+package dynamodb
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awsdynamodb "github.com/aws/aws-sdk-go/service/dynamodb"
+
+	"github.com/philippgille/gokv/util"
+)
+
+// verAttrName is used as the table column name for the version attribute
+// written when Options.EnableVersioning is set. It's stored as a DynamoDB
+// number (N) so CompareAndSwap can express its condition as
+// "attribute_not_exists(ver) OR ver = :currentVer".
+var verAttrName = "ver"
+
+// errCASRequiresV1 is returned by SetIfNotExists and CompareAndSwap when
+// called on a Client constructed via NewClientV2, which doesn't support
+// them yet.
+var errCASRequiresV1 = errors.New("dynamodb: SetIfNotExists/CompareAndSwap are only supported on a Client created with NewClient, not NewClientV2")
+
+// errCompareAndSwapRequiresVersioning is returned by CompareAndSwap when
+// Options.EnableVersioning wasn't set on the Client.
+var errCompareAndSwapRequiresVersioning = errors.New("dynamodb: CompareAndSwap requires Options.EnableVersioning to be set")
+
+// SetIfNotExists stores the given value for the given key only if the key
+// doesn't already exist, using a conditional PutItem. It returns (true, nil)
+// if the value was stored, or (false, nil) if the key already existed.
+// The key must not be "" and the value must not be nil.
+func (c Client) SetIfNotExists(k string, v interface{}) (bool, error) {
+	if c.v1svc == nil {
+		return false, errCASRequiresV1
+	}
+	if err := util.CheckKeyAndValue(k, v); err != nil {
+		return false, err
+	}
+
+	data, err := c.codec.Marshal(v)
+	if err != nil {
+		return false, err
+	}
+
+	item := map[string]*awsdynamodb.AttributeValue{
+		keyAttrName: {S: &k},
+		valAttrName: {B: data},
+	}
+	if c.enableVersioning {
+		zero := "0"
+		item[verAttrName] = &awsdynamodb.AttributeValue{N: &zero}
+	}
+	if c.ttl > 0 {
+		epoch := strconv.FormatInt(time.Now().Add(c.ttl).Unix(), 10)
+		item[ttlAttrName] = &awsdynamodb.AttributeValue{N: &epoch}
+	}
+
+	condition := "attribute_not_exists(" + keyAttrName + ")"
+	_, err = c.v1svc.PutItem(&awsdynamodb.PutItemInput{
+		TableName:           &c.tableName,
+		Item:                item,
+		ConditionExpression: &condition,
+	})
+	if err != nil {
+		if isConditionalCheckFailed(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// setVersioned performs Set's write when Options.EnableVersioning is set. It
+// reads the item's current "ver" (treating a missing one as 0, same as
+// CompareAndSwap) and writes it back incremented by one, so that an item
+// written through Set still carries a usable version the next time
+// CompareAndSwap reads it.
+func (c Client) setVersioned(k string, data []byte, expiresAt time.Time) error {
+	getOutput, err := c.v1svc.GetItem(&awsdynamodb.GetItemInput{
+		TableName:      &c.tableName,
+		Key:            map[string]*awsdynamodb.AttributeValue{keyAttrName: {S: &k}},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return err
+	}
+
+	var currentVer int64
+	if getOutput.Item != nil {
+		if verAttr := getOutput.Item[verAttrName]; verAttr != nil && verAttr.N != nil {
+			currentVer, err = strconv.ParseInt(*verAttr.N, 10, 64)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	newVerStr := strconv.FormatInt(currentVer+1, 10)
+
+	item := map[string]*awsdynamodb.AttributeValue{
+		keyAttrName: {S: &k},
+		valAttrName: {B: data},
+		verAttrName: {N: &newVerStr},
+	}
+	if !expiresAt.IsZero() {
+		epoch := strconv.FormatInt(expiresAt.Unix(), 10)
+		item[ttlAttrName] = &awsdynamodb.AttributeValue{N: &epoch}
+	}
+
+	_, err = c.v1svc.PutItem(&awsdynamodb.PutItemInput{
+		TableName: &c.tableName,
+		Item:      item,
+	})
+	return err
+}
+
+// CompareAndSwap replaces the value stored for k with new, but only if the
+// value currently stored for k matches old and hasn't been concurrently
+// modified since it was last read. It returns (true, nil) if the swap
+// happened, or (false, nil) if old didn't match the current value, or if a
+// concurrent write won the race. It requires Options.EnableVersioning.
+func (c Client) CompareAndSwap(k string, old, new interface{}) (bool, error) {
+	if c.v1svc == nil {
+		return false, errCASRequiresV1
+	}
+	if !c.enableVersioning {
+		return false, errCompareAndSwapRequiresVersioning
+	}
+	if err := util.CheckKeyAndValue(k, new); err != nil {
+		return false, err
+	}
+	if err := util.CheckVal(old); err != nil {
+		return false, err
+	}
+
+	oldData, err := c.codec.Marshal(old)
+	if err != nil {
+		return false, err
+	}
+	newData, err := c.codec.Marshal(new)
+	if err != nil {
+		return false, err
+	}
+
+	getOutput, err := c.v1svc.GetItem(&awsdynamodb.GetItemInput{
+		TableName:      &c.tableName,
+		Key:            map[string]*awsdynamodb.AttributeValue{keyAttrName: {S: &k}},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return false, err
+	}
+	if getOutput.Item == nil {
+		return false, nil
+	}
+	currentVal := getOutput.Item[valAttrName]
+	if currentVal == nil || !bytes.Equal(currentVal.B, oldData) {
+		return false, nil
+	}
+
+	var currentVer int64
+	if verAttr := getOutput.Item[verAttrName]; verAttr != nil && verAttr.N != nil {
+		currentVer, err = strconv.ParseInt(*verAttr.N, 10, 64)
+		if err != nil {
+			return false, err
+		}
+	}
+	currentVerStr := strconv.FormatInt(currentVer, 10)
+	newVerStr := strconv.FormatInt(currentVer+1, 10)
+
+	newItem := map[string]*awsdynamodb.AttributeValue{
+		keyAttrName: {S: &k},
+		valAttrName: {B: newData},
+		verAttrName: {N: &newVerStr},
+	}
+	if c.ttl > 0 {
+		epoch := strconv.FormatInt(time.Now().Add(c.ttl).Unix(), 10)
+		newItem[ttlAttrName] = &awsdynamodb.AttributeValue{N: &epoch}
+	}
+
+	condition := "attribute_not_exists(" + verAttrName + ") OR " + verAttrName + " = :currentVer"
+	_, err = c.v1svc.PutItem(&awsdynamodb.PutItemInput{
+		TableName:           &c.tableName,
+		Item:                newItem,
+		ConditionExpression: &condition,
+		ExpressionAttributeValues: map[string]*awsdynamodb.AttributeValue{
+			":currentVer": {N: &currentVerStr},
+		},
+	})
+	if err != nil {
+		if isConditionalCheckFailed(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// isConditionalCheckFailed reports whether err is the AWS error DynamoDB
+// returns when a ConditionExpression evaluates to false.
+func isConditionalCheckFailed(err error) bool {
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) {
+		return awsErr.Code() == awsdynamodb.ErrCodeConditionalCheckFailedException
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package dynamodb
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	. "github.com/onsi/gomega"
+
+	"github.com/philippgille/gokv/encoding"
+)
+
+// casMockDynamoDB is an in-memory DynamoDB double that actually understands
+// the two ConditionExpressions cas.go issues, so it can tell a real
+// ConditionalCheckFailedException apart from a successful write the way the
+// real service would.
+type casMockDynamoDB struct {
+	dynamodbiface.DynamoDBAPI
+	items map[string]map[string]*dynamodb.AttributeValue
+}
+
+func newCASMockDynamoDB() *casMockDynamoDB {
+	return &casMockDynamoDB{items: map[string]map[string]*dynamodb.AttributeValue{}}
+}
+
+func (m *casMockDynamoDB) DescribeTableWithContext(_ aws.Context, i *dynamodb.DescribeTableInput, _ ...request.Option) (*dynamodb.DescribeTableOutput, error) {
+	return &dynamodb.DescribeTableOutput{Table: &dynamodb.TableDescription{TableName: i.TableName}}, nil
+}
+
+func (m *casMockDynamoDB) GetItem(i *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	return &dynamodb.GetItemOutput{Item: m.items[*i.Key[keyAttrName].S]}, nil
+}
+
+func (m *casMockDynamoDB) PutItem(i *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	k := *i.Item[keyAttrName].S
+	if i.ConditionExpression != nil && !m.evalCondition(*i.ConditionExpression, m.items[k], i.ExpressionAttributeValues) {
+		return nil, awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "condition failed", nil)
+	}
+	m.items[k] = i.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (m *casMockDynamoDB) evalCondition(cond string, item map[string]*dynamodb.AttributeValue, attrs map[string]*dynamodb.AttributeValue) bool {
+	switch cond {
+	case "attribute_not_exists(" + keyAttrName + ")":
+		return item == nil
+	case "attribute_not_exists(" + verAttrName + ") OR " + verAttrName + " = :currentVer":
+		verAttr := item[verAttrName]
+		if verAttr == nil || verAttr.N == nil {
+			return true
+		}
+		return *verAttr.N == *attrs[":currentVer"].N
+	}
+	return false
+}
+
+func TestSetIfNotExists(t *testing.T) {
+	g := NewGomegaWithT(t)
+	svc := newCASMockDynamoDB()
+	c, err := NewClient(Options{Service: svc, TableName: "t"})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	ok, err := c.SetIfNotExists("k1", "first")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ok).To(BeTrue())
+
+	ok, err = c.SetIfNotExists("k1", "second")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ok).To(BeFalse())
+}
+
+func TestCompareAndSwap(t *testing.T) {
+	g := NewGomegaWithT(t)
+	svc := newCASMockDynamoDB()
+	c, err := NewClient(Options{Service: svc, TableName: "t", EnableVersioning: true})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// Simulate an item written before EnableVersioning was turned on (or
+	// via SetMulti, which never writes "ver"): it has no "ver" attribute.
+	// CompareAndSwap must still treat that as version 0, not reject it.
+	data, err := encoding.JSON.Marshal("original")
+	g.Expect(err).NotTo(HaveOccurred())
+	svc.items["k1"] = map[string]*dynamodb.AttributeValue{
+		keyAttrName: {S: aws.String("k1")},
+		valAttrName: {B: data},
+	}
+
+	ok, err := c.CompareAndSwap("k1", "original", "updated")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ok).To(BeTrue())
+
+	// A stale old value must be rejected.
+	ok, err = c.CompareAndSwap("k1", "original", "again")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ok).To(BeFalse())
+
+	// The current value swaps again, now racing against the version left
+	// by the previous successful swap.
+	ok, err = c.CompareAndSwap("k1", "updated", "final")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ok).To(BeTrue())
+}
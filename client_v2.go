@@ -0,0 +1,64 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/philippgille/gokv/encoding"
+)
+
+// OptionsV2 are the options for a DynamoDB client backed by the AWS SDK v2.
+type OptionsV2 struct {
+	TableName string
+	Client    DynamoDBAPIV2
+	// Optional (encoding.JSON by default).
+	Codec encoding.Codec
+	// ConsistentRead makes Get use a strongly consistent read instead of
+	// DynamoDB's default eventually consistent one. Optional (false by
+	// default).
+	ConsistentRead bool
+}
+
+// DefaultOptionsV2 is an OptionsV2 object with default values.
+// TableName: "" (must be set), Client: nil (must be set), Codec: encoding.JSON
+var DefaultOptionsV2 = OptionsV2{
+	Codec: encoding.JSON,
+}
+
+// NewClientV2 creates a new DynamoDB client backed by the AWS SDK v2, for
+// callers that have already migrated to aws-sdk-go-v2 and don't want to pull
+// in the v1 SDK just for this module. Set/Get/Delete/Close work identically
+// to a Client created with NewClient. The batch and other v1-only APIs
+// (e.g. GetMulti) aren't supported on a v2-backed Client yet.
+func NewClientV2(options OptionsV2) (Client, error) {
+	result := Client{}
+
+	if options.Client == nil {
+		return result, errors.New("no dynamodb client provided")
+	}
+
+	if options.TableName == "" {
+		return result, errors.New("no options.TableName specified")
+	}
+	result.tableName = options.TableName
+	result.svc = v2Store{
+		svc:            options.Client,
+		tableName:      options.TableName,
+		consistentRead: options.ConsistentRead,
+	}
+
+	// Also serves as connection test.
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := result.svc.describeTable(timeoutCtx, options.TableName); err != nil {
+		return Client{}, err
+	}
+
+	if options.Codec == nil {
+		options.Codec = encoding.JSON
+	}
+	result.codec = options.Codec
+
+	return result, nil
+}
@@ -0,0 +1,79 @@
+package dynamodb
+
+import (
+	"context"
+	"testing"
+
+	dynamodbv2 "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	. "github.com/onsi/gomega"
+)
+
+// mockDynamoDBV2 is an in-memory AWS SDK v2 DynamoDB double.
+type mockDynamoDBV2 struct {
+	items              map[string]map[string]types.AttributeValue
+	lastConsistentRead *bool
+}
+
+func newMockDynamoDBV2() *mockDynamoDBV2 {
+	return &mockDynamoDBV2{items: map[string]map[string]types.AttributeValue{}}
+}
+
+func (m *mockDynamoDBV2) PutItem(_ context.Context, i *dynamodbv2.PutItemInput, _ ...func(*dynamodbv2.Options)) (*dynamodbv2.PutItemOutput, error) {
+	k := i.Item[keyAttrName].(*types.AttributeValueMemberS).Value
+	m.items[k] = i.Item
+	return &dynamodbv2.PutItemOutput{}, nil
+}
+
+func (m *mockDynamoDBV2) GetItem(_ context.Context, i *dynamodbv2.GetItemInput, _ ...func(*dynamodbv2.Options)) (*dynamodbv2.GetItemOutput, error) {
+	m.lastConsistentRead = i.ConsistentRead
+	k := i.Key[keyAttrName].(*types.AttributeValueMemberS).Value
+	return &dynamodbv2.GetItemOutput{Item: m.items[k]}, nil
+}
+
+func (m *mockDynamoDBV2) DeleteItem(_ context.Context, i *dynamodbv2.DeleteItemInput, _ ...func(*dynamodbv2.Options)) (*dynamodbv2.DeleteItemOutput, error) {
+	k := i.Key[keyAttrName].(*types.AttributeValueMemberS).Value
+	delete(m.items, k)
+	return &dynamodbv2.DeleteItemOutput{}, nil
+}
+
+func (m *mockDynamoDBV2) DescribeTable(_ context.Context, i *dynamodbv2.DescribeTableInput, _ ...func(*dynamodbv2.Options)) (*dynamodbv2.DescribeTableOutput, error) {
+	return &dynamodbv2.DescribeTableOutput{Table: &types.TableDescription{TableName: i.TableName}}, nil
+}
+
+func TestNewClientV2(t *testing.T) {
+	g := NewGomegaWithT(t)
+	svc := newMockDynamoDBV2()
+
+	// Built directly, not from DefaultOptionsV2: Codec must still default to
+	// encoding.JSON rather than leaving the Client unusable.
+	c, err := NewClientV2(OptionsV2{TableName: "t", Client: svc})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(c.Set("k1", "hello")).To(Succeed())
+
+	var got string
+	found, err := c.Get("k1", &got)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(found).To(BeTrue())
+	g.Expect(got).To(Equal("hello"))
+
+	g.Expect(c.Delete("k1")).To(Succeed())
+	found, err = c.Get("k1", &got)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(found).To(BeFalse())
+}
+
+func TestNewClientV2ConsistentRead(t *testing.T) {
+	g := NewGomegaWithT(t)
+	svc := newMockDynamoDBV2()
+
+	c, err := NewClientV2(OptionsV2{TableName: "t", Client: svc, ConsistentRead: true})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	var got string
+	_, err = c.Get("k1", &got)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(svc.lastConsistentRead).NotTo(BeNil())
+	g.Expect(*svc.lastConsistentRead).To(BeTrue())
+}
@@ -0,0 +1,75 @@
+package dynamodb
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	. "github.com/onsi/gomega"
+)
+
+// daxLikeDynamoDB stands in for a DAX client: it serves GetItem/PutItem/
+// DeleteItem but, like github.com/aws/aws-dax-go, doesn't implement
+// DescribeTable, so Options.ProbeService is required to provision it.
+type daxLikeDynamoDB struct {
+	dynamodbiface.DynamoDBAPI
+	items              map[string]*dynamodb.AttributeValue
+	lastConsistentRead *bool
+}
+
+func (m *daxLikeDynamoDB) DescribeTableWithContext(_ aws.Context, _ *dynamodb.DescribeTableInput, _ ...request.Option) (*dynamodb.DescribeTableOutput, error) {
+	return nil, errors.New("dax: operation not supported")
+}
+
+func (m *daxLikeDynamoDB) GetItem(i *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	m.lastConsistentRead = i.ConsistentRead
+	k := *i.Key[keyAttrName].S
+	if v, ok := m.items[k]; ok {
+		return &dynamodb.GetItemOutput{Item: map[string]*dynamodb.AttributeValue{keyAttrName: {S: &k}, valAttrName: v}}, nil
+	}
+	return &dynamodb.GetItemOutput{}, nil
+}
+
+// probeDynamoDB is the plain DynamoDB client passed as Options.ProbeService,
+// standing in for a non-DAX client used only for control-plane calls.
+type probeDynamoDB struct {
+	dynamodbiface.DynamoDBAPI
+	describeCalls int
+}
+
+func (m *probeDynamoDB) DescribeTableWithContext(_ aws.Context, i *dynamodb.DescribeTableInput, _ ...request.Option) (*dynamodb.DescribeTableOutput, error) {
+	m.describeCalls++
+	return &dynamodb.DescribeTableOutput{Table: &dynamodb.TableDescription{TableName: i.TableName}}, nil
+}
+
+func TestNewClientWithDAXRequiresProbeService(t *testing.T) {
+	g := NewGomegaWithT(t)
+	dax := &daxLikeDynamoDB{items: map[string]*dynamodb.AttributeValue{}}
+
+	_, err := NewClient(Options{Service: dax, TableName: "t"})
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestNewClientWithDAXAndProbeService(t *testing.T) {
+	g := NewGomegaWithT(t)
+	dax := &daxLikeDynamoDB{items: map[string]*dynamodb.AttributeValue{}}
+	probe := &probeDynamoDB{}
+
+	c, err := NewClient(Options{
+		Service:        dax,
+		ProbeService:   probe,
+		TableName:      "t",
+		ConsistentRead: true,
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(probe.describeCalls).To(Equal(1))
+
+	var got string
+	_, err = c.Get("k1", &got)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(dax.lastConsistentRead).NotTo(BeNil())
+	g.Expect(*dax.lastConsistentRead).To(BeTrue())
+}
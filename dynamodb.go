@@ -5,7 +5,6 @@ import (
 	"errors"
 	"time"
 
-	awsdynamodb "github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 
 	"github.com/philippgille/gokv/encoding"
@@ -18,11 +17,28 @@ var keyAttrName = "k"
 // "v" is used as table column name for the value.
 var valAttrName = "v"
 
+// "ttl" is used as table column name for the Time to Live attribute, written
+// when Options.TTL is set.
+var ttlAttrName = "ttl"
+
 // Client is a gokv.Store implementation for DynamoDB.
 type Client struct {
-	svc       dynamodbiface.DynamoDBAPI
+	svc store
+	// v1svc is non-nil only when the Client is backed by the AWS SDK v1
+	// (i.e. constructed via NewClient). It is used by operations, such as
+	// the batch APIs, that haven't been ported to also work against the
+	// SDK v2 adapter yet.
+	v1svc     dynamodbiface.DynamoDBAPI
 	tableName string
 	codec     encoding.Codec
+	// enableVersioning mirrors Options.EnableVersioning; see CompareAndSwap.
+	enableVersioning bool
+	// ttl mirrors Options.TTL; see Set.
+	ttl time.Duration
+	// controlSvc handles DescribeTable/CreateTable/DescribeTimeToLive/
+	// UpdateTimeToLive. It's the same as v1svc, except when Options.Service
+	// is a DAX client and Options.ProbeService was set; see NewClient.
+	controlSvc dynamodbiface.DynamoDBAPI
 }
 
 // Set stores the given value for the given key.
@@ -39,22 +55,19 @@ func (c Client) Set(k string, v interface{}) error {
 		return err
 	}
 
-	item := make(map[string]*awsdynamodb.AttributeValue)
-	item[keyAttrName] = &awsdynamodb.AttributeValue{
-		S: &k,
-	}
-	item[valAttrName] = &awsdynamodb.AttributeValue{
-		B: data,
-	}
-	putItemInput := awsdynamodb.PutItemInput{
-		TableName: &c.tableName,
-		Item:      item,
+	var expiresAt time.Time
+	if c.ttl > 0 {
+		expiresAt = time.Now().Add(c.ttl)
 	}
-	_, err = c.svc.PutItem(&putItemInput)
-	if err != nil {
-		return err
+
+	// Keep the "ver" attribute alive across plain Sets, so a workflow that
+	// mixes Set and CompareAndSwap on the same key doesn't lose version
+	// tracking the next time CompareAndSwap reads the item.
+	if c.enableVersioning && c.v1svc != nil {
+		return c.setVersioned(k, data, expiresAt)
 	}
-	return nil
+
+	return c.svc.putItem(context.Background(), k, data, expiresAt)
 }
 
 // Get retrieves the stored value for the given key.
@@ -68,28 +81,10 @@ func (c Client) Get(k string, v interface{}) (found bool, err error) {
 		return false, err
 	}
 
-	key := make(map[string]*awsdynamodb.AttributeValue)
-	key[keyAttrName] = &awsdynamodb.AttributeValue{
-		S: &k,
-	}
-	getItemInput := awsdynamodb.GetItemInput{
-		TableName: &c.tableName,
-		Key:       key,
+	data, found, err := c.svc.getItem(context.Background(), k)
+	if err != nil || !found {
+		return found, err
 	}
-	getItemOutput, err := c.svc.GetItem(&getItemInput)
-	if err != nil {
-		return false, err
-	} else if getItemOutput.Item == nil {
-		// Return false if the key-value pair doesn't exist
-		return false, nil
-	}
-	attributeVal := getItemOutput.Item[valAttrName]
-	if attributeVal == nil {
-		// Return false if there's no value
-		// TODO: Maybe return an error? Behaviour should be consistent across all implementations.
-		return false, nil
-	}
-	data := attributeVal.B
 
 	return true, c.codec.Unmarshal(data, v)
 }
@@ -102,16 +97,7 @@ func (c Client) Delete(k string) error {
 		return err
 	}
 
-	key := make(map[string]*awsdynamodb.AttributeValue)
-	key[keyAttrName] = &awsdynamodb.AttributeValue{
-		S: &k,
-	}
-	deleteItemInput := awsdynamodb.DeleteItemInput{
-		TableName: &c.tableName,
-		Key:       key,
-	}
-	_, err := c.svc.DeleteItem(&deleteItemInput)
-	return err
+	return c.svc.deleteItem(context.Background(), k)
 }
 
 // Close closes the client.
@@ -123,9 +109,60 @@ func (c Client) Close() error {
 // Options are the options for the DynamoDB client.
 type Options struct {
 	TableName string
+	// Service can also be a DynamoDB Accelerator (DAX) client, e.g.
+	// *github.com/aws/aws-dax-go/dax.Dax, for read-heavy workloads. DAX
+	// only implements DynamoDB's data-plane operations (GetItem/PutItem/
+	// DeleteItem/...), so pass a plain dynamodb.New(sess) client as
+	// ProbeService as well, or NewClient's connection test (and
+	// CreateTableIfMissing/TTL setup) will fail:
+	//
+	//   dax, _ := dax.New(&dax.Config{HostPorts: []string{"my-cluster.amazonaws.com:8111"}})
+	//   client, err := dynamodb.NewClient(dynamodb.Options{
+	//       TableName:    "gokv",
+	//       Service:      dax,
+	//       ProbeService: awsdynamodb.New(session.Must(session.NewSession())),
+	//   })
 	Service dynamodbiface.DynamoDBAPI
 	// Optional (encoding.JSON by default).
 	Codec encoding.Codec
+	// EnableVersioning makes CompareAndSwap available by storing an
+	// additional "ver" (N) attribute alongside every item written through
+	// Set, SetIfNotExists or CompareAndSwap on a Client created with
+	// NewClient (SetMulti doesn't write it). Optional (false by default), for backward
+	// compatibility with tables written to before this was added.
+	EnableVersioning bool
+	// TTL, if set, is written as the "ttl" attribute on every item stored
+	// through Set, as the Unix time it expires at. Optional (disabled by
+	// default). NewClient additionally enables Time to Live on the "ttl"
+	// attribute via UpdateTimeToLive if it isn't already enabled, so that
+	// DynamoDB actually expires the items.
+	TTL time.Duration
+	// CreateTableIfMissing makes NewClient create TableName, with "k" as
+	// its hash key, if DescribeTable reports it doesn't exist yet.
+	// Optional (false by default, in which case NewClient fails if the
+	// table doesn't already exist).
+	CreateTableIfMissing bool
+	// BillingMode, if set to dynamodb.BillingModePayPerRequest, creates the
+	// table without provisioned throughput. Only used when
+	// CreateTableIfMissing is set. Optional (dynamodb.BillingModeProvisioned
+	// by default, using ReadCapacityUnits/WriteCapacityUnits).
+	BillingMode string
+	// ReadCapacityUnits and WriteCapacityUnits set the table's provisioned
+	// throughput when CreateTableIfMissing creates it and BillingMode isn't
+	// dynamodb.BillingModePayPerRequest. Optional (5 by default).
+	ReadCapacityUnits  int64
+	WriteCapacityUnits int64
+	// ConsistentRead makes Get use a strongly consistent read instead of
+	// DynamoDB's default eventually consistent one. Optional (false by
+	// default).
+	ConsistentRead bool
+	// ProbeService is used for DescribeTable/CreateTable/DescribeTimeToLive/
+	// UpdateTimeToLive instead of Service. Set this when Service is a
+	// DAX client (github.com/aws/aws-dax-go), which only implements
+	// DynamoDB's data-plane operations (GetItem/PutItem/DeleteItem/...) and
+	// returns an error for these control-plane ones. Optional (Service is
+	// used for everything by default).
+	ProbeService dynamodbiface.DynamoDBAPI
 }
 
 // DefaultOptions is an Options object with default values.
@@ -137,33 +174,56 @@ var DefaultOptions = Options{
 	Codec: encoding.JSON,
 }
 
-// NewClient creates a new DynamoDB client.
+// NewClient creates a new DynamoDB client backed by the AWS SDK v1.
 func NewClient(options Options) (Client, error) {
 	result := Client{}
 
 	if options.Service == nil {
 		return result, errors.New("no dynamodb service provided")
 	}
-	result.svc = options.Service
+	result.v1svc = options.Service
+	result.controlSvc = options.Service
+	if options.ProbeService != nil {
+		result.controlSvc = options.ProbeService
+	}
 
 	// Set default values
 	if options.TableName == "" {
 		return result, errors.New("no options.TableName specified")
 	}
 	result.tableName = options.TableName
+	result.svc = v1Store{
+		svc:            options.Service,
+		controlSvc:     result.controlSvc,
+		tableName:      options.TableName,
+		consistentRead: options.ConsistentRead,
+	}
+	result.enableVersioning = options.EnableVersioning
+	result.ttl = options.TTL
 
 	// Also serves as connection test.
 	// Use context for timeout.
 	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
-	describeTableInput := awsdynamodb.DescribeTableInput{
-		TableName: &options.TableName,
-	}
-	_, err := result.svc.DescribeTableWithContext(timeoutCtx, &describeTableInput)
+	err := result.svc.describeTable(timeoutCtx, options.TableName)
 	if err != nil {
-		return result, err
+		if !options.CreateTableIfMissing || !isResourceNotFound(err) {
+			return Client{}, err
+		}
+		if err := result.createTable(options); err != nil {
+			return Client{}, err
+		}
 	}
 
+	if options.TTL > 0 {
+		if err := result.ensureTTLEnabled(); err != nil {
+			return Client{}, err
+		}
+	}
+
+	if options.Codec == nil {
+		options.Codec = encoding.JSON
+	}
 	result.codec = options.Codec
 
 	return result, nil
@@ -40,6 +40,17 @@ func (m mockDynamoDB) PutItem(i *dynamodb.PutItemInput) (*dynamodb.PutItemOutput
 	return nil, nil
 }
 
+func (m mockDynamoDB) DescribeTimeToLive(i *dynamodb.DescribeTimeToLiveInput) (*dynamodb.DescribeTimeToLiveOutput, error) {
+	return &dynamodb.DescribeTimeToLiveOutput{
+		TimeToLiveDescription: &dynamodb.TimeToLiveDescription{
+			TimeToLiveStatus: aws.String(dynamodb.TimeToLiveStatusDisabled),
+		},
+	}, nil
+}
+
+func (m mockDynamoDB) UpdateTimeToLive(i *dynamodb.UpdateTimeToLiveInput) (*dynamodb.UpdateTimeToLiveOutput, error) {
+	return &dynamodb.UpdateTimeToLiveOutput{}, nil
+}
 
 
 
@@ -0,0 +1,137 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsdynamodb "github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// errIterateRequiresV1 is returned by Iterate when called on a Client
+// constructed via NewClientV2, which doesn't support it yet.
+var errIterateRequiresV1 = errors.New("dynamodb: Iterate is only supported on a Client created with NewClient, not NewClientV2")
+
+// IterateOptions control pagination and throughput of Iterate.
+type IterateOptions struct {
+	// PageSize caps the number of items fetched per Scan page (DynamoDB's
+	// own 1 MB per-page limit still applies). Optional (DynamoDB's default
+	// of 0, meaning unlimited, is used if not set).
+	PageSize int64
+	// Parallelism splits the scan into this many segments, each walked
+	// concurrently, using DynamoDB's TotalSegments/Segment parallel scan.
+	// Optional (1 by default, i.e. a single sequential scan).
+	Parallelism int
+	// PageThrottle is waited between pages of a single segment, to avoid
+	// exhausting provisioned throughput. Optional (no throttling by
+	// default).
+	PageThrottle time.Duration
+}
+
+// Iterate walks every key-value pair in the table whose key has the given
+// prefix (all of them if prefix is ""), calling fn with the raw
+// (still codec-encoded) value for each one. It pages through the table with
+// Scan, stopping as soon as fn returns a non-nil error or ctx is cancelled;
+// that error is then returned from Iterate.
+//
+// This isn't part of the gokv.Store interface; it exists because DynamoDB
+// doesn't offer a cheaper way to list keys, and operators building admin
+// tooling on top of a Client repeatedly need one.
+func (c Client) Iterate(ctx context.Context, prefix string, fn func(k string, raw []byte) error, opts ...IterateOptions) error {
+	if c.v1svc == nil {
+		return errIterateRequiresV1
+	}
+
+	var options IterateOptions
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+	parallelism := options.Parallelism
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	// Cancelled on the first error, so the other segments stop too.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var once sync.Once
+	var firstErr error
+	setErr := func(err error) {
+		once.Do(func() { firstErr = err })
+		cancel()
+	}
+
+	var wg sync.WaitGroup
+	for segment := 0; segment < parallelism; segment++ {
+		segment := segment
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.scanSegment(ctx, prefix, segment, parallelism, options, fn); err != nil {
+				setErr(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return firstErr
+}
+
+// scanSegment pages through a single segment (or the whole table, when
+// totalSegments is 1) with ScanPages.
+func (c Client) scanSegment(ctx context.Context, prefix string, segment, totalSegments int, options IterateOptions, fn func(k string, raw []byte) error) error {
+	input := &awsdynamodb.ScanInput{
+		TableName: &c.tableName,
+	}
+	if options.PageSize > 0 {
+		input.Limit = &options.PageSize
+	}
+	if totalSegments > 1 {
+		segmentVal := int64(segment)
+		totalSegmentsVal := int64(totalSegments)
+		input.Segment = &segmentVal
+		input.TotalSegments = &totalSegmentsVal
+	}
+	if prefix != "" {
+		input.FilterExpression = aws.String("begins_with(" + keyAttrName + ", :prefix)")
+		input.ExpressionAttributeValues = map[string]*awsdynamodb.AttributeValue{
+			":prefix": {S: &prefix},
+		}
+	}
+
+	var callbackErr error
+	firstPage := true
+	err := c.v1svc.ScanPagesWithContext(ctx, input, func(output *awsdynamodb.ScanOutput, lastPage bool) bool {
+		if ctx.Err() != nil {
+			callbackErr = ctx.Err()
+			return false
+		}
+		if !firstPage && options.PageThrottle > 0 {
+			time.Sleep(options.PageThrottle)
+		}
+		firstPage = false
+
+		for _, item := range output.Items {
+			k := item[keyAttrName]
+			if k == nil || k.S == nil {
+				continue
+			}
+			var data []byte
+			if raw := item[valAttrName]; raw != nil {
+				data = raw.B
+			}
+			if err := fn(*k.S, data); err != nil {
+				callbackErr = err
+				return false
+			}
+		}
+		return true
+	})
+	if callbackErr != nil {
+		return callbackErr
+	}
+	return err
+}
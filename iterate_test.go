@@ -0,0 +1,119 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	. "github.com/onsi/gomega"
+)
+
+// iterateMockDynamoDB is an in-memory DynamoDB double whose ScanPagesWithContext
+// pages through m.items two at a time, honoring Segment/TotalSegments and
+// FilterExpression the same (limited) way the real service would for the
+// begins_with(k, :prefix) expression Iterate builds.
+type iterateMockDynamoDB struct {
+	dynamodbiface.DynamoDBAPI
+	items map[string]string // key -> value
+}
+
+func (m *iterateMockDynamoDB) DescribeTableWithContext(_ aws.Context, i *dynamodb.DescribeTableInput, _ ...request.Option) (*dynamodb.DescribeTableOutput, error) {
+	return &dynamodb.DescribeTableOutput{Table: &dynamodb.TableDescription{TableName: i.TableName}}, nil
+}
+
+func (m *iterateMockDynamoDB) ScanPagesWithContext(_ aws.Context, input *dynamodb.ScanInput, fn func(*dynamodb.ScanOutput, bool) bool, _ ...request.Option) error {
+	var keys []string
+	for k := range m.items {
+		if input.TotalSegments != nil && int64(hashKey(k))%*input.TotalSegments != *input.Segment {
+			continue
+		}
+		if input.FilterExpression != nil {
+			prefix := *input.ExpressionAttributeValues[":prefix"].S
+			if len(k) < len(prefix) || k[:len(prefix)] != prefix {
+				continue
+			}
+		}
+		keys = append(keys, k)
+	}
+
+	const pageSize = 2
+	for len(keys) > 0 {
+		n := pageSize
+		if n > len(keys) {
+			n = len(keys)
+		}
+		page := &dynamodb.ScanOutput{}
+		for _, k := range keys[:n] {
+			k := k
+			page.Items = append(page.Items, map[string]*dynamodb.AttributeValue{
+				keyAttrName: {S: &k},
+				valAttrName: {B: []byte(m.items[k])},
+			})
+		}
+		keys = keys[n:]
+		if !fn(page, len(keys) == 0) {
+			return nil
+		}
+	}
+	return nil
+}
+
+func hashKey(k string) int {
+	h := 0
+	for _, r := range k {
+		h += int(r)
+	}
+	return h
+}
+
+func TestIterate(t *testing.T) {
+	g := NewGomegaWithT(t)
+	svc := &iterateMockDynamoDB{items: map[string]string{}}
+	for i := 0; i < 7; i++ {
+		svc.items[fmt.Sprintf("a%d", i)] = fmt.Sprintf("v%d", i)
+	}
+	svc.items["b0"] = "other"
+
+	c, err := NewClient(Options{Service: svc, TableName: "t"})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	var mu sync.Mutex
+	seen := map[string]string{}
+	err = c.Iterate(context.Background(), "a", func(k string, raw []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		seen[k] = string(raw)
+		return nil
+	}, IterateOptions{PageSize: 2, Parallelism: 3})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(seen).To(HaveLen(7))
+	g.Expect(seen).NotTo(HaveKey("b0"))
+	for i := 0; i < 7; i++ {
+		g.Expect(seen[fmt.Sprintf("a%d", i)]).To(Equal(fmt.Sprintf("v%d", i)))
+	}
+}
+
+func TestIterateStopsOnCancelledContext(t *testing.T) {
+	g := NewGomegaWithT(t)
+	svc := &iterateMockDynamoDB{items: map[string]string{}}
+	for i := 0; i < 10; i++ {
+		svc.items[fmt.Sprintf("a%d", i)] = "v"
+	}
+
+	c, err := NewClient(Options{Service: svc, TableName: "t"})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = c.Iterate(ctx, "", func(k string, raw []byte) error {
+		return nil
+	})
+	g.Expect(err).To(Equal(context.Canceled))
+}
@@ -0,0 +1,117 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awsdynamodb "github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// defaultCapacityUnits is used for ReadCapacityUnits/WriteCapacityUnits when
+// CreateTableIfMissing creates a provisioned-throughput table and the caller
+// didn't specify either.
+const defaultCapacityUnits = 5
+
+// createTableTimeout bounds how long NewClient waits for a table it just
+// created to become ACTIVE.
+const createTableTimeout = 2 * time.Minute
+
+// createTable creates c.tableName with keyAttrName as its hash key, and
+// blocks until it's ACTIVE.
+func (c Client) createTable(options Options) error {
+	billingMode := options.BillingMode
+	if billingMode == "" {
+		billingMode = awsdynamodb.BillingModeProvisioned
+	}
+
+	input := &awsdynamodb.CreateTableInput{
+		TableName: &c.tableName,
+		AttributeDefinitions: []*awsdynamodb.AttributeDefinition{
+			{
+				AttributeName: &keyAttrName,
+				AttributeType: aws.String(awsdynamodb.ScalarAttributeTypeS),
+			},
+		},
+		KeySchema: []*awsdynamodb.KeySchemaElement{
+			{
+				AttributeName: &keyAttrName,
+				KeyType:       aws.String(awsdynamodb.KeyTypeHash),
+			},
+		},
+		BillingMode: &billingMode,
+	}
+	if billingMode == awsdynamodb.BillingModeProvisioned {
+		readCapacity := options.ReadCapacityUnits
+		if readCapacity == 0 {
+			readCapacity = defaultCapacityUnits
+		}
+		writeCapacity := options.WriteCapacityUnits
+		if writeCapacity == 0 {
+			writeCapacity = defaultCapacityUnits
+		}
+		input.ProvisionedThroughput = &awsdynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  &readCapacity,
+			WriteCapacityUnits: &writeCapacity,
+		}
+	}
+
+	if _, err := c.controlSvc.CreateTable(input); err != nil && !isResourceInUse(err) {
+		return err
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), createTableTimeout)
+	defer cancel()
+	return c.controlSvc.WaitUntilTableExistsWithContext(timeoutCtx, &awsdynamodb.DescribeTableInput{
+		TableName: &c.tableName,
+	})
+}
+
+// ensureTTLEnabled enables DynamoDB's Time to Live on ttlAttrName if it
+// isn't enabled already. It's idempotent: calling UpdateTimeToLive while TTL
+// is already enabled on the same attribute returns a ValidationException, so
+// DescribeTimeToLive is checked first.
+func (c Client) ensureTTLEnabled() error {
+	describeOutput, err := c.controlSvc.DescribeTimeToLive(&awsdynamodb.DescribeTimeToLiveInput{
+		TableName: &c.tableName,
+	})
+	if err != nil {
+		return err
+	}
+	if describeOutput.TimeToLiveDescription != nil &&
+		aws.StringValue(describeOutput.TimeToLiveDescription.TimeToLiveStatus) == awsdynamodb.TimeToLiveStatusEnabled {
+		return nil
+	}
+
+	_, err = c.controlSvc.UpdateTimeToLive(&awsdynamodb.UpdateTimeToLiveInput{
+		TableName: &c.tableName,
+		TimeToLiveSpecification: &awsdynamodb.TimeToLiveSpecification{
+			AttributeName: &ttlAttrName,
+			Enabled:       aws.Bool(true),
+		},
+	})
+	return err
+}
+
+// isResourceNotFound reports whether err is the AWS error DynamoDB returns
+// when the requested table doesn't exist.
+func isResourceNotFound(err error) bool {
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) {
+		return awsErr.Code() == awsdynamodb.ErrCodeResourceNotFoundException
+	}
+	return false
+}
+
+// isResourceInUse reports whether err is the AWS error DynamoDB returns when
+// CreateTable is called for a table that already exists, e.g. because it was
+// created by a concurrent NewClient call.
+func isResourceInUse(err error) bool {
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) {
+		return awsErr.Code() == awsdynamodb.ErrCodeResourceInUseException
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+package dynamodb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	. "github.com/onsi/gomega"
+)
+
+// provisionMockDynamoDB is an in-memory double for the control-plane calls
+// createTable/ensureTTLEnabled make. tableExists controls whether
+// DescribeTableWithContext reports the table missing (so CreateTableIfMissing
+// kicks in), and createTableErr lets tests simulate CreateTable racing a
+// concurrent NewClient call.
+type provisionMockDynamoDB struct {
+	dynamodbiface.DynamoDBAPI
+
+	tableExists    bool
+	createTableErr error
+
+	ttlStatus string
+
+	createTableInput *dynamodb.CreateTableInput
+	updateTTLCalls   int
+}
+
+func (m *provisionMockDynamoDB) DescribeTableWithContext(_ aws.Context, i *dynamodb.DescribeTableInput, _ ...request.Option) (*dynamodb.DescribeTableOutput, error) {
+	if !m.tableExists {
+		return nil, awserr.New(dynamodb.ErrCodeResourceNotFoundException, "no such table", nil)
+	}
+	return &dynamodb.DescribeTableOutput{Table: &dynamodb.TableDescription{TableName: i.TableName}}, nil
+}
+
+func (m *provisionMockDynamoDB) CreateTable(i *dynamodb.CreateTableInput) (*dynamodb.CreateTableOutput, error) {
+	m.createTableInput = i
+	if m.createTableErr != nil {
+		return nil, m.createTableErr
+	}
+	m.tableExists = true
+	return &dynamodb.CreateTableOutput{}, nil
+}
+
+func (m *provisionMockDynamoDB) WaitUntilTableExistsWithContext(_ aws.Context, _ *dynamodb.DescribeTableInput, _ ...request.WaiterOption) error {
+	return nil
+}
+
+func (m *provisionMockDynamoDB) DescribeTimeToLive(_ *dynamodb.DescribeTimeToLiveInput) (*dynamodb.DescribeTimeToLiveOutput, error) {
+	return &dynamodb.DescribeTimeToLiveOutput{
+		TimeToLiveDescription: &dynamodb.TimeToLiveDescription{
+			TimeToLiveStatus: aws.String(m.ttlStatus),
+		},
+	}, nil
+}
+
+func (m *provisionMockDynamoDB) UpdateTimeToLive(_ *dynamodb.UpdateTimeToLiveInput) (*dynamodb.UpdateTimeToLiveOutput, error) {
+	m.updateTTLCalls++
+	return &dynamodb.UpdateTimeToLiveOutput{}, nil
+}
+
+func TestCreateTableIfMissingProvisionedDefaults(t *testing.T) {
+	g := NewGomegaWithT(t)
+	svc := &provisionMockDynamoDB{}
+
+	_, err := NewClient(Options{
+		Service:              svc,
+		TableName:            "t",
+		CreateTableIfMissing: true,
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(svc.createTableInput).NotTo(BeNil())
+	g.Expect(aws.StringValue(svc.createTableInput.BillingMode)).To(Equal(dynamodb.BillingModeProvisioned))
+	g.Expect(*svc.createTableInput.ProvisionedThroughput.ReadCapacityUnits).To(Equal(int64(defaultCapacityUnits)))
+	g.Expect(*svc.createTableInput.ProvisionedThroughput.WriteCapacityUnits).To(Equal(int64(defaultCapacityUnits)))
+}
+
+func TestCreateTableIfMissingProvisionedCustomCapacity(t *testing.T) {
+	g := NewGomegaWithT(t)
+	svc := &provisionMockDynamoDB{}
+
+	_, err := NewClient(Options{
+		Service:              svc,
+		TableName:            "t",
+		CreateTableIfMissing: true,
+		ReadCapacityUnits:    7,
+		WriteCapacityUnits:   9,
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(*svc.createTableInput.ProvisionedThroughput.ReadCapacityUnits).To(Equal(int64(7)))
+	g.Expect(*svc.createTableInput.ProvisionedThroughput.WriteCapacityUnits).To(Equal(int64(9)))
+}
+
+func TestCreateTableIfMissingPayPerRequest(t *testing.T) {
+	g := NewGomegaWithT(t)
+	svc := &provisionMockDynamoDB{}
+
+	_, err := NewClient(Options{
+		Service:              svc,
+		TableName:            "t",
+		CreateTableIfMissing: true,
+		BillingMode:          dynamodb.BillingModePayPerRequest,
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(aws.StringValue(svc.createTableInput.BillingMode)).To(Equal(dynamodb.BillingModePayPerRequest))
+	g.Expect(svc.createTableInput.ProvisionedThroughput).To(BeNil())
+}
+
+func TestCreateTableIfMissingToleratesConcurrentCreate(t *testing.T) {
+	g := NewGomegaWithT(t)
+	svc := &provisionMockDynamoDB{
+		createTableErr: awserr.New(dynamodb.ErrCodeResourceInUseException, "table already being created", nil),
+	}
+
+	_, err := NewClient(Options{
+		Service:              svc,
+		TableName:            "t",
+		CreateTableIfMissing: true,
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+}
+
+func TestEnsureTTLEnabledSkipsWhenAlreadyEnabled(t *testing.T) {
+	g := NewGomegaWithT(t)
+	svc := &provisionMockDynamoDB{tableExists: true, ttlStatus: dynamodb.TimeToLiveStatusEnabled}
+
+	_, err := NewClient(Options{
+		Service:   svc,
+		TableName: "t",
+		TTL:       10 * time.Second,
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(svc.updateTTLCalls).To(Equal(0))
+}
+
+func TestEnsureTTLEnabledUpdatesWhenDisabled(t *testing.T) {
+	g := NewGomegaWithT(t)
+	svc := &provisionMockDynamoDB{tableExists: true, ttlStatus: dynamodb.TimeToLiveStatusDisabled}
+
+	_, err := NewClient(Options{
+		Service:   svc,
+		TableName: "t",
+		TTL:       10 * time.Second,
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(svc.updateTTLCalls).To(Equal(1))
+}
@@ -0,0 +1,19 @@
+package dynamodb
+
+import (
+	"context"
+	"time"
+)
+
+// store is the internal interface that both the AWS SDK v1 and v2 DynamoDB
+// clients are adapted to, so that Set/Get/Delete/Close behave identically
+// regardless of which SDK generation a Client is backed by.
+type store interface {
+	// putItem stores data for k. If expiresAt isn't the zero Time, it's
+	// additionally written as the "ttl" attribute, as a Unix timestamp, for
+	// DynamoDB's Time to Live to pick up (see Options.TTL).
+	putItem(ctx context.Context, k string, data []byte, expiresAt time.Time) error
+	getItem(ctx context.Context, k string) (data []byte, found bool, err error)
+	deleteItem(ctx context.Context, k string) error
+	describeTable(ctx context.Context, tableName string) error
+}
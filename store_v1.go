@@ -0,0 +1,77 @@
+package dynamodb
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	awsdynamodb "github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// v1Store adapts an AWS SDK v1 dynamodbiface.DynamoDBAPI client to the
+// internal store interface.
+type v1Store struct {
+	svc dynamodbiface.DynamoDBAPI
+	// controlSvc handles DescribeTable. It's usually the same client as
+	// svc, except when svc is a DAX client (see Options.ProbeService),
+	// which doesn't implement DescribeTable.
+	controlSvc     dynamodbiface.DynamoDBAPI
+	tableName      string
+	consistentRead bool
+}
+
+func (s v1Store) putItem(ctx context.Context, k string, data []byte, expiresAt time.Time) error {
+	item := map[string]*awsdynamodb.AttributeValue{
+		keyAttrName: {S: &k},
+		valAttrName: {B: data},
+	}
+	if !expiresAt.IsZero() {
+		epoch := strconv.FormatInt(expiresAt.Unix(), 10)
+		item[ttlAttrName] = &awsdynamodb.AttributeValue{N: &epoch}
+	}
+	_, err := s.svc.PutItem(&awsdynamodb.PutItemInput{
+		TableName: &s.tableName,
+		Item:      item,
+	})
+	return err
+}
+
+func (s v1Store) getItem(ctx context.Context, k string) ([]byte, bool, error) {
+	key := map[string]*awsdynamodb.AttributeValue{
+		keyAttrName: {S: &k},
+	}
+	output, err := s.svc.GetItem(&awsdynamodb.GetItemInput{
+		TableName:      &s.tableName,
+		Key:            key,
+		ConsistentRead: &s.consistentRead,
+	})
+	if err != nil {
+		return nil, false, err
+	} else if output.Item == nil {
+		return nil, false, nil
+	}
+	attributeVal := output.Item[valAttrName]
+	if attributeVal == nil {
+		return nil, false, nil
+	}
+	return attributeVal.B, true, nil
+}
+
+func (s v1Store) deleteItem(ctx context.Context, k string) error {
+	key := map[string]*awsdynamodb.AttributeValue{
+		keyAttrName: {S: &k},
+	}
+	_, err := s.svc.DeleteItem(&awsdynamodb.DeleteItemInput{
+		TableName: &s.tableName,
+		Key:       key,
+	})
+	return err
+}
+
+func (s v1Store) describeTable(ctx context.Context, tableName string) error {
+	_, err := s.controlSvc.DescribeTableWithContext(ctx, &awsdynamodb.DescribeTableInput{
+		TableName: &tableName,
+	})
+	return err
+}
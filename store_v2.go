@@ -0,0 +1,87 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	dynamodbv2 "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// DynamoDBAPIV2 is the subset of the AWS SDK v2 DynamoDB client that this
+// package needs. *dynamodbv2.Client satisfies it, and a test double can
+// implement it directly without spinning up a real DynamoDB endpoint.
+type DynamoDBAPIV2 interface {
+	PutItem(ctx context.Context, params *dynamodbv2.PutItemInput, optFns ...func(*dynamodbv2.Options)) (*dynamodbv2.PutItemOutput, error)
+	GetItem(ctx context.Context, params *dynamodbv2.GetItemInput, optFns ...func(*dynamodbv2.Options)) (*dynamodbv2.GetItemOutput, error)
+	DeleteItem(ctx context.Context, params *dynamodbv2.DeleteItemInput, optFns ...func(*dynamodbv2.Options)) (*dynamodbv2.DeleteItemOutput, error)
+	DescribeTable(ctx context.Context, params *dynamodbv2.DescribeTableInput, optFns ...func(*dynamodbv2.Options)) (*dynamodbv2.DescribeTableOutput, error)
+}
+
+// v2Store adapts an AWS SDK v2 DynamoDBAPIV2 client to the internal store
+// interface.
+type v2Store struct {
+	svc            DynamoDBAPIV2
+	tableName      string
+	consistentRead bool
+}
+
+func (s v2Store) putItem(ctx context.Context, k string, data []byte, expiresAt time.Time) error {
+	item := map[string]types.AttributeValue{
+		keyAttrName: &types.AttributeValueMemberS{Value: k},
+		valAttrName: &types.AttributeValueMemberB{Value: data},
+	}
+	if !expiresAt.IsZero() {
+		item[ttlAttrName] = &types.AttributeValueMemberN{Value: strconv.FormatInt(expiresAt.Unix(), 10)}
+	}
+	_, err := s.svc.PutItem(ctx, &dynamodbv2.PutItemInput{
+		TableName: &s.tableName,
+		Item:      item,
+	})
+	return err
+}
+
+func (s v2Store) getItem(ctx context.Context, k string) ([]byte, bool, error) {
+	key := map[string]types.AttributeValue{
+		keyAttrName: &types.AttributeValueMemberS{Value: k},
+	}
+	output, err := s.svc.GetItem(ctx, &dynamodbv2.GetItemInput{
+		TableName:      &s.tableName,
+		Key:            key,
+		ConsistentRead: &s.consistentRead,
+	})
+	if err != nil {
+		return nil, false, err
+	} else if output.Item == nil {
+		return nil, false, nil
+	}
+	attributeVal, ok := output.Item[valAttrName]
+	if !ok {
+		return nil, false, nil
+	}
+	valMember, ok := attributeVal.(*types.AttributeValueMemberB)
+	if !ok {
+		return nil, false, errors.New("dynamodb: unexpected type for value attribute")
+	}
+	return valMember.Value, true, nil
+}
+
+func (s v2Store) deleteItem(ctx context.Context, k string) error {
+	key := map[string]types.AttributeValue{
+		keyAttrName: &types.AttributeValueMemberS{Value: k},
+	}
+	_, err := s.svc.DeleteItem(ctx, &dynamodbv2.DeleteItemInput{
+		TableName: &s.tableName,
+		Key:       key,
+	})
+	return err
+}
+
+func (s v2Store) describeTable(ctx context.Context, tableName string) error {
+	_, err := s.svc.DescribeTable(ctx, &dynamodbv2.DescribeTableInput{
+		TableName: &tableName,
+	})
+	return err
+}